@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package policy centralizes the executable allow-list, deny-list, and
+// hashing checks the provider consults before running any command, so
+// operators can safely embed this module in shared modules without granting
+// arbitrary code execution.
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Policy governs which resolved executables the provider is allowed to
+// invoke. The zero value allows everything.
+type Policy struct {
+	// AllowedCommands, if non-empty, restricts execution to executables
+	// whose resolved absolute path matches at least one glob pattern.
+	AllowedCommands []string
+	// DeniedCommands, checked before AllowedCommands, rejects execution of
+	// any executable whose resolved absolute path matches a glob pattern.
+	DeniedCommands []string
+	// RequireAbsolutePath rejects executables that did not resolve to an
+	// absolute path.
+	RequireAbsolutePath bool
+	// ExecutableSHA256 maps a resolved executable path to the lowercase hex
+	// SHA-256 digest it must match on disk.
+	ExecutableSHA256 map[string]string
+}
+
+// Check resolves path (following symlinks) and validates it against the
+// policy, returning an error describing the first violation encountered.
+// path is expected to already be an absolute path, as returned by
+// exec.LookPath.
+func (p Policy) Check(path string) error {
+	if p.RequireAbsolutePath && !filepath.IsAbs(path) {
+		return fmt.Errorf("executable %q is not an absolute path", path)
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("resolving symlinks for %q: %w", path, err)
+	}
+
+	if matched, pattern := matchAny(p.DeniedCommands, resolved); matched {
+		return fmt.Errorf("executable %q is denied by pattern %q", resolved, pattern)
+	}
+
+	if len(p.AllowedCommands) > 0 {
+		if matched, _ := matchAny(p.AllowedCommands, resolved); !matched {
+			return fmt.Errorf("executable %q does not match any allowed_commands pattern", resolved)
+		}
+	}
+
+	if want, ok := lookupHash(p.ExecutableSHA256, path, resolved); ok {
+		got, err := sha256File(resolved)
+		if err != nil {
+			return fmt.Errorf("hashing %q: %w", resolved, err)
+		}
+		if !strings.EqualFold(got, want) {
+			return fmt.Errorf("executable %q has sha256 %s, expected %s", resolved, got, want)
+		}
+	}
+
+	return nil
+}
+
+// lookupHash looks up an expected digest by either the pre- or
+// post-symlink-resolution path, since operators may reasonably key
+// executable_sha256 by either.
+func lookupHash(digests map[string]string, paths ...string) (string, bool) {
+	for _, p := range paths {
+		if want, ok := digests[p]; ok {
+			return want, true
+		}
+	}
+	return "", false
+}
+
+// matchAny reports whether path matches any glob pattern. On Windows, paths
+// are case-preserving but not case-sensitive, so the comparison is
+// case-insensitive there; elsewhere it is case-sensitive.
+func matchAny(patterns []string, path string) (matched bool, matchedPattern string) {
+	candidate := normalizeForMatch(path)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(normalizeForMatch(pattern), candidate); ok {
+			return true, pattern
+		}
+	}
+	return false, ""
+}
+
+func normalizeForMatch(path string) string {
+	if runtime.GOOS == "windows" {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeExecutable(t *testing.T, dir, name string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte("#!/bin/sh\necho hi\n"), 0o755); err != nil {
+		t.Fatalf("writing %q: %s", p, err)
+	}
+	return p
+}
+
+func TestCheckResolvesSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	real := writeExecutable(t, dir, "real-bin")
+	link := filepath.Join(dir, "link-bin")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks not supported: %s", err)
+	}
+
+	p := Policy{DeniedCommands: []string{real}}
+	if err := p.Check(link); err == nil {
+		t.Fatalf("expected Check to deny %q via its symlink target %q, got nil error", link, real)
+	}
+
+	p = Policy{AllowedCommands: []string{real}}
+	if err := p.Check(link); err != nil {
+		t.Fatalf("expected Check to allow %q via its symlink target %q, got: %s", link, real, err)
+	}
+}
+
+func TestCheckAllowedDenied(t *testing.T) {
+	dir := t.TempDir()
+	allowed := writeExecutable(t, dir, "allowed-bin")
+	denied := writeExecutable(t, dir, "denied-bin")
+
+	p := Policy{
+		AllowedCommands: []string{filepath.Join(dir, "allowed-*")},
+		DeniedCommands:  []string{filepath.Join(dir, "denied-*")},
+	}
+
+	if err := p.Check(allowed); err != nil {
+		t.Errorf("expected %q to be allowed, got: %s", allowed, err)
+	}
+	if err := p.Check(denied); err == nil {
+		t.Errorf("expected %q to be denied, got nil error", denied)
+	}
+
+	other := writeExecutable(t, dir, "other-bin")
+	if err := p.Check(other); err == nil {
+		t.Errorf("expected %q to be rejected for not matching any allowed_commands pattern", other)
+	}
+}
+
+func TestCheckRequireAbsolutePath(t *testing.T) {
+	p := Policy{RequireAbsolutePath: true}
+	if err := p.Check("relative-bin"); err == nil {
+		t.Fatal("expected Check to reject a non-absolute path when RequireAbsolutePath is set")
+	}
+}
+
+func TestCheckExecutableSHA256(t *testing.T) {
+	dir := t.TempDir()
+	bin := writeExecutable(t, dir, "hashed-bin")
+
+	// sha256 of "#!/bin/sh\necho hi\n"
+	want, err := sha256File(bin)
+	if err != nil {
+		t.Fatalf("sha256File: %s", err)
+	}
+
+	p := Policy{ExecutableSHA256: map[string]string{bin: want}}
+	if err := p.Check(bin); err != nil {
+		t.Errorf("expected matching sha256 to pass, got: %s", err)
+	}
+
+	p = Policy{ExecutableSHA256: map[string]string{bin: "0000000000000000000000000000000000000000000000000000000000000000"}}
+	if err := p.Check(bin); err == nil {
+		t.Error("expected mismatched sha256 to be rejected, got nil error")
+	}
+}
+
+func TestMatchAnyWindowsCasing(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("case-insensitive matching only applies on windows")
+	}
+
+	matched, _ := matchAny([]string{`C:\Tools\Allowed.EXE`}, `c:\tools\allowed.exe`)
+	if !matched {
+		t.Error("expected matchAny to be case-insensitive on windows")
+	}
+}
+
+func TestMatchAnyCaseSensitiveElsewhere(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("covered by TestMatchAnyWindowsCasing")
+	}
+
+	matched, _ := matchAny([]string{"/usr/bin/Allowed"}, "/usr/bin/allowed")
+	if matched {
+		t.Error("expected matchAny to be case-sensitive outside windows")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package progress reports the lifecycle of long-running provider
+// operations (begin/report/end), in the spirit of the LSP work-done-progress
+// tokens used by terraform-ls around terraform.init.
+package progress
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Reporter observes the lifecycle of a single long-running operation
+// identified by a caller-supplied token. Callers must call Close once the
+// operation has ended, to release any sink resources (open files, sockets)
+// the Reporter may hold.
+type Reporter interface {
+	Begin(title string)
+	Report(msg string, pct int)
+	End(msg string)
+	Close() error
+}
+
+// NoopReporter discards every event. It is convenient to inject in place of
+// a real sink where no progress output is wanted. The provider does not use
+// it by default: with no sink configured, progress goes through tflog.
+type NoopReporter struct{}
+
+func (NoopReporter) Begin(string)       {}
+func (NoopReporter) Report(string, int) {}
+func (NoopReporter) End(string)         {}
+func (NoopReporter) Close() error       { return nil }
+
+// tflogReporter emits progress.begin/progress.report/progress.end events
+// through tflog, tagged with the operation's token.
+type tflogReporter struct {
+	ctx   context.Context
+	token string
+	start time.Time
+}
+
+// NewTflogReporter returns a Reporter that logs through tflog.
+func NewTflogReporter(ctx context.Context, token string) Reporter {
+	return &tflogReporter{ctx: ctx, token: token}
+}
+
+func (r *tflogReporter) Begin(title string) {
+	r.start = time.Now()
+	tflog.Info(r.ctx, "progress.begin", map[string]interface{}{
+		"token": r.token,
+		"title": title,
+	})
+}
+
+func (r *tflogReporter) Report(msg string, pct int) {
+	tflog.Info(r.ctx, "progress.report", map[string]interface{}{
+		"token":      r.token,
+		"message":    msg,
+		"percentage": pct,
+		"elapsed_ms": time.Since(r.start).Milliseconds(),
+	})
+}
+
+func (r *tflogReporter) End(msg string) {
+	tflog.Info(r.ctx, "progress.end", map[string]interface{}{
+		"token":      r.token,
+		"message":    msg,
+		"elapsed_ms": time.Since(r.start).Milliseconds(),
+	})
+}
+
+func (r *tflogReporter) Close() error { return nil }
+
+// event is the JSON-lines shape written by the file and unix-socket sinks.
+type event struct {
+	Token      string `json:"token"`
+	Kind       string `json:"kind"`
+	Title      string `json:"title,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Percentage int    `json:"percentage,omitempty"`
+	ElapsedMS  int64  `json:"elapsed_ms"`
+}
+
+// writerReporter serializes events as JSON lines to a writer. It backs both
+// the file and unix-socket sinks, which differ only in how the writer is
+// opened.
+type writerReporter struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	token  string
+	start  time.Time
+}
+
+func (r *writerReporter) emit(kind, title, msg string, pct int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = json.NewEncoder(r.w).Encode(event{
+		Token:      r.token,
+		Kind:       kind,
+		Title:      title,
+		Message:    msg,
+		Percentage: pct,
+		ElapsedMS:  time.Since(r.start).Milliseconds(),
+	})
+	_ = r.w.Flush()
+}
+
+func (r *writerReporter) Begin(title string) {
+	r.start = time.Now()
+	r.emit("begin", title, "", 0)
+}
+
+func (r *writerReporter) Report(msg string, pct int) {
+	r.emit("report", "", msg, pct)
+}
+
+func (r *writerReporter) End(msg string) {
+	r.emit("end", "", msg, 0)
+}
+
+// Close flushes any buffered output and closes the underlying file or
+// socket. It is safe to call even if the writer is already empty.
+func (r *writerReporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	flushErr := r.w.Flush()
+	closeErr := r.closer.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// NewFileReporter returns a Reporter that appends JSON-line progress events
+// to the file at path, creating it if necessary.
+func NewFileReporter(path, token string) (Reporter, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening progress file %q: %w", path, err)
+	}
+	return &writerReporter{w: bufio.NewWriter(f), closer: f, token: token}, nil
+}
+
+// NewUnixSocketReporter returns a Reporter that streams JSON-line progress
+// events to a listener on the Unix domain socket at path.
+func NewUnixSocketReporter(path, token string) (Reporter, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("dialing progress socket %q: %w", path, err)
+	}
+	return &writerReporter{w: bufio.NewWriter(conn), closer: conn, token: token}, nil
+}
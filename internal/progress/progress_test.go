@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package progress
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeReporter is a test double that records every event it receives, so
+// callers can inject it in place of a real sink and assert on the resulting
+// event stream.
+type fakeReporter struct {
+	events []string
+	closed bool
+}
+
+func (f *fakeReporter) Begin(title string)       { f.events = append(f.events, "begin:"+title) }
+func (f *fakeReporter) Report(msg string, _ int) { f.events = append(f.events, "report:"+msg) }
+func (f *fakeReporter) End(msg string)           { f.events = append(f.events, "end:"+msg) }
+func (f *fakeReporter) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestFakeReporterRecordsEventStream(t *testing.T) {
+	var r Reporter = &fakeReporter{}
+	r.Begin("running")
+	r.Report("halfway", 50)
+	r.End("completed")
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	f := r.(*fakeReporter)
+	want := []string{"begin:running", "report:halfway", "end:completed"}
+	if len(f.events) != len(want) {
+		t.Fatalf("got events %v, want %v", f.events, want)
+	}
+	for i, ev := range want {
+		if f.events[i] != ev {
+			t.Errorf("event %d = %q, want %q", i, f.events[i], ev)
+		}
+	}
+	if !f.closed {
+		t.Error("expected Close to be recorded")
+	}
+}
+
+func TestNewFileReporterClosesUnderlyingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	r, err := NewFileReporter(path, "token-1")
+	if err != nil {
+		t.Fatalf("NewFileReporter: %s", err)
+	}
+
+	r.Begin("running")
+	r.End("completed")
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading progress file: %s", err)
+	}
+	if len(data) == 0 {
+		t.Error("expected progress file to contain flushed events")
+	}
+
+	wr := r.(*writerReporter)
+	if err := wr.closer.(*os.File).Close(); err == nil {
+		t.Error("expected file to already be closed by Reporter.Close")
+	}
+}
+
+func TestNewUnixSocketReporterStreamsAndCloses(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "progress.sock")
+	ln, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("listening on unix socket: %s", err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	r, err := NewUnixSocketReporter(sockPath, "token-2")
+	if err != nil {
+		t.Fatalf("NewUnixSocketReporter: %s", err)
+	}
+
+	conn := <-accepted
+	defer conn.Close()
+
+	r.Begin("running")
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("reading from socket: %s", err)
+	}
+	if n == 0 {
+		t.Error("expected a begin event to be written to the socket")
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+}
@@ -4,47 +4,175 @@
 package provider
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os/exec"
+	"runtime"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/Spirit91/terraform-provider-test/internal/progress"
 )
 
+// jsonStdoutPreviewLen bounds how much of a malformed JSON stdout payload is
+// echoed back in diagnostics, so a runaway process can't flood the plan output.
+const jsonStdoutPreviewLen = 256
+
+// maxScanTokenSize raises the stdout/stderr line-scanning limit well past
+// bufio's default 64KiB, so a single long line (e.g. a single-line JSON
+// result) isn't silently truncated. Shared by both the data source and the
+// resource's lifecycle command output capture.
+const maxScanTokenSize = 10 * 1024 * 1024
+
 var (
-	_ datasource.DataSource = (*testDataSource)(nil)
+	_ datasource.DataSource              = (*testDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*testDataSource)(nil)
 )
 
 func NewTestDataSource() datasource.DataSource {
 	return &testDataSource{}
 }
 
-type testDataSource struct{}
+// defaultInterpreter returns the shell wrapper used to run a string-form
+// command when no explicit `interpreter` attribute is supplied.
+func defaultInterpreter() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C"}
+	}
+	return []string{"/bin/sh", "-c"}
+}
+
+// policyTargets returns the executable path(s) that the provider's command
+// policy should be checked against. For argv-form `program` invocations,
+// argv[0] already is the program. For string-form `command` invocations,
+// argv[0] is the interpreter (e.g. `/bin/sh`), not the program the
+// interpreter runs — so the policy would otherwise only ever see the shell.
+// This also resolves and checks the command's own first word, on a
+// best-effort basis: it only covers the common case of a single command, not
+// arbitrary shell constructs (pipelines, subshells, etc.).
+func policyTargets(hasProgram bool, command, resolvedArgv0 string) []string {
+	targets := []string{resolvedArgv0}
+	if hasProgram {
+		return targets
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return targets
+	}
+
+	if resolvedCommand, err := exec.LookPath(fields[0]); err == nil {
+		targets = append(targets, resolvedCommand)
+	}
+	return targets
+}
+
+type testDataSource struct {
+	providerData *testProviderData
+}
 
 func (n *testDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName
 }
 
+func (n *testDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*testProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *testProviderData, got: %T.", req.ProviderData),
+		)
+		return
+	}
+	n.providerData = data
+}
+
+// reporterFor builds the progress.Reporter selected by the provider's
+// `progress_reporter` configuration, tagged with token, falling back to
+// tflog if no sink is configured or the configured sink can't be opened.
+func (n *testDataSource) reporterFor(ctx context.Context, token string) progress.Reporter {
+	if n.providerData != nil {
+		switch n.providerData.ProgressReporter {
+		case "file":
+			if r, err := progress.NewFileReporter(n.providerData.ProgressFile, token); err == nil {
+				return r
+			} else {
+				tflog.Warn(ctx, "Falling back to tflog progress reporter", map[string]interface{}{"error": err.Error()})
+			}
+		case "unix-socket":
+			if r, err := progress.NewUnixSocketReporter(n.providerData.ProgressSocket, token); err == nil {
+				return r
+			} else {
+				tflog.Warn(ctx, "Falling back to tflog progress reporter", map[string]interface{}{"error": err.Error()})
+			}
+		}
+	}
+	return progress.NewTflogReporter(ctx, token)
+}
+
 func (n *testDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Executes a local command, just like `local-exec`, and returns its output.",
 		Attributes: map[string]schema.Attribute{
 			"command": schema.StringAttribute{
-				Description: "The command to execute.",
-				Required:    true,
+				Description: "The command to execute, interpreted by a shell. Exactly one of `command` or `program` must be set.",
+				Optional:    true,
+			},
+			"program": schema.ListAttribute{
+				Description: "The command and its arguments as a list, executed directly without a shell. Exactly one of `command` or `program` must be set.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"interpreter": schema.ListAttribute{
+				Description: "The interpreter and flags used to run `command`, e.g. `[\"/bin/bash\", \"-c\"]` or `[\"pwsh\", \"-Command\"]`. Defaults to `[\"/bin/sh\", \"-c\"]` on Unix and `[\"cmd\", \"/C\"]` on Windows. Ignored when `program` is set.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
 			},
 			"working_dir": schema.StringAttribute{
 				Description: "Working directory of the program. Defaults to the current directory.",
 				Optional:    true,
 			},
+			"query": schema.MapAttribute{
+				Description: "A map of string values passed to the command as a JSON object on stdin. Setting `query` enables JSON mode; the command must then write a flat JSON object of string to string on stdout, which is decoded into `result`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"mode": schema.StringAttribute{
+				Description: "Set to `\"json\"` to opt into the JSON query/result protocol without providing `query`. JSON mode is implied whenever `query` is set.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("json"),
+				},
+			},
+			"result": schema.MapAttribute{
+				Description: "The JSON object decoded from the command's stdout, as a map of string to string. Only populated in JSON mode.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
 			"output": schema.StringAttribute{
-				Description: "The standard output of the executed command.",
+				Description: "The standard output of the executed command. Not populated in JSON mode; see `result` instead.",
 				Computed:    true,
 			},
 			"error": schema.StringAttribute{
@@ -71,61 +199,206 @@ func (n *testDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	// Récupération des valeurs
 	command := config.Command.ValueString()
 	workingDir := config.WorkingDir.ValueString()
+	hasProgram := !config.Program.IsNull() && !config.Program.IsUnknown()
+	hasCommand := !config.Command.IsNull() && command != ""
 
-	if command == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("command"),
-			"Missing Command",
-			"The command cannot be empty. Please specify a valid shell command.",
+	if hasProgram == hasCommand {
+		resp.Diagnostics.AddError(
+			"Invalid Command Configuration",
+			"Exactly one of \"command\" or \"program\" must be specified.",
 		)
 		return
 	}
 
+	var argv []string
+	var interpreter []string
+	if hasProgram {
+		diags = config.Program.ElementsAs(ctx, &argv, false)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else {
+		if !config.Interpreter.IsNull() && !config.Interpreter.IsUnknown() {
+			diags = config.Interpreter.ElementsAs(ctx, &interpreter, false)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		} else {
+			interpreter = defaultInterpreter()
+		}
+		argv = append(append([]string{}, interpreter...), command)
+	}
+
 	// Vérification de l'exécutable
-	_, err := exec.LookPath(strings.Fields(command)[0])
+	resolvedPath, err := exec.LookPath(argv[0])
 	if err != nil {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("command"),
 			"Command Not Found",
-			fmt.Sprintf("The command '%s' was not found. Ensure it's installed and accessible.", command),
+			fmt.Sprintf("The executable '%s' was not found. Ensure it's installed and accessible.", argv[0]),
 		)
 		return
 	}
 
+	if n.providerData != nil {
+		for _, target := range policyTargets(hasProgram, command, resolvedPath) {
+			if err := n.providerData.Policy.Check(target); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("command"),
+					"Executable Denied By Policy",
+					fmt.Sprintf("The executable %q was refused by the provider's command policy: %s", target, err),
+				)
+				return
+			}
+		}
+	}
+
 	// Préparation de l'exécution
-	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd := exec.CommandContext(ctx, resolvedPath, argv[1:]...)
 	if workingDir != "" {
 		cmd.Dir = workingDir
 	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+	jsonMode := !config.Query.IsNull() && !config.Query.IsUnknown() || config.Mode.ValueString() == "json"
+
+	var stdin bytes.Buffer
+	if jsonMode && !config.Query.IsNull() && !config.Query.IsUnknown() {
+		var query map[string]string
+		diags = config.Query.ElementsAs(ctx, &query, false)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		queryBytes, marshalErr := json.Marshal(query)
+		if marshalErr != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("query"),
+				"Invalid Query",
+				fmt.Sprintf("Unable to marshal \"query\" to JSON: %s", marshalErr),
+			)
+			return
+		}
+		stdin.Write(queryBytes)
+		cmd.Stdin = &stdin
+	}
+
+	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	tflog.Trace(ctx, "Executing command", map[string]interface{}{"command": command})
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		resp.Diagnostics.AddError("Command Setup Failed", fmt.Sprintf("Unable to attach to stdout: %s", err))
+		return
+	}
+
+	token := uuid.New().String()
+	reporter := n.reporterFor(ctx, token)
+	defer func() {
+		if closeErr := reporter.Close(); closeErr != nil {
+			tflog.Warn(ctx, "Failed to close progress reporter", map[string]interface{}{"error": closeErr.Error()})
+		}
+	}()
+	reporter.Begin(fmt.Sprintf("Running %v", argv))
 
-	err = cmd.Run()
+	tflog.Trace(ctx, "Executing command", map[string]interface{}{
+		"argv":           argv,
+		"interpreter":    interpreter,
+		"working_dir":    workingDir,
+		"json_mode":      jsonMode,
+		"progress_token": token,
+	})
+
+	if startErr := cmd.Start(); startErr != nil {
+		reporter.End(fmt.Sprintf("failed to start: %s", startErr))
+		resp.Diagnostics.AddAttributeError(
+			path.Root("command"),
+			"Command Execution Failed",
+			fmt.Sprintf("Unable to start command.\n\nArgv: %v\nError: %s", argv, startErr),
+		)
+		return
+	}
+
+	var stdout bytes.Buffer
+	var scanErr error
+	scanDone := make(chan struct{})
+	go func() {
+		defer close(scanDone)
+		scanner := bufio.NewScanner(stdoutPipe)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxScanTokenSize)
+		for scanner.Scan() {
+			line := scanner.Text()
+			stdout.WriteString(line)
+			stdout.WriteString("\n")
+			reporter.Report(line, 0)
+		}
+		scanErr = scanner.Err()
+	}()
+	<-scanDone
+
+	err = cmd.Wait()
+	if err == nil && scanErr != nil {
+		err = fmt.Errorf("reading command stdout: %w", scanErr)
+	}
 	outputStr := stdout.String()
 	errorStr := stderr.String()
 
 	tflog.Trace(ctx, "Executed command", map[string]interface{}{
-		"command": command,
-		"output":  outputStr,
-		"error":   errorStr,
+		"argv":   argv,
+		"output": outputStr,
+		"error":  errorStr,
 	})
 
 	// Si la commande a échoué
 	if err != nil {
+		exitCode := -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+		if ctx.Err() != nil {
+			reporter.End(fmt.Sprintf("cancelled: %s", ctx.Err()))
+		} else {
+			reporter.End(fmt.Sprintf("failed: %s", err))
+		}
 		resp.Diagnostics.AddAttributeError(
 			path.Root("command"),
 			"Command Execution Failed",
-			fmt.Sprintf("Command execution failed.\n\nCommand: %s\nError: %s\nStderr: %s", command, err, errorStr),
+			fmt.Sprintf(
+				"Command execution failed.\n\nArgv: %v\nInterpreter: %v\nWorking Directory: %s\nExit Code: %d\nError: %s\nStderr: %s\nStdout Preview: %s",
+				argv, interpreter, workingDir, exitCode, err, errorStr, truncateForPreview(outputStr, jsonStdoutPreviewLen),
+			),
 		)
 		return
 	}
+	reporter.End("completed")
 
 	// Mettre à jour l'état
-	config.Output = types.StringValue(outputStr)
+	if jsonMode {
+		var result map[string]string
+		if unmarshalErr := json.Unmarshal([]byte(outputStr), &result); unmarshalErr != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("query"),
+				"Invalid JSON Result",
+				fmt.Sprintf(
+					"The command's stdout could not be parsed as a flat JSON object of string to string.\n\nError: %s\nStdout Preview: %s",
+					unmarshalErr, truncateForPreview(outputStr, jsonStdoutPreviewLen),
+				),
+			)
+			return
+		}
+
+		resultValue, diagsResult := types.MapValueFrom(ctx, types.StringType, result)
+		resp.Diagnostics.Append(diagsResult...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		config.Result = resultValue
+		config.Output = types.StringValue("")
+	} else {
+		config.Result = types.MapNull(types.StringType)
+		config.Output = types.StringValue(outputStr)
+	}
 	config.Error = types.StringValue(errorStr)
 	config.ID = types.StringValue("-")
 
@@ -133,10 +406,24 @@ func (n *testDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	resp.Diagnostics.Append(diags...)
 }
 
+// truncateForPreview trims s to at most n bytes, appending an ellipsis marker
+// when truncation occurs, for safe inclusion in diagnostics.
+func truncateForPreview(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "... (truncated)"
+}
+
 type testDataSourceModel struct {
-	Command    types.String `tfsdk:"command"`
-	WorkingDir types.String `tfsdk:"working_dir"`
-	Output     types.String `tfsdk:"output"`
-	Error      types.String `tfsdk:"error"`
-	ID         types.String `tfsdk:"id"`
+	Command     types.String `tfsdk:"command"`
+	Program     types.List   `tfsdk:"program"`
+	Interpreter types.List   `tfsdk:"interpreter"`
+	WorkingDir  types.String `tfsdk:"working_dir"`
+	Query       types.Map    `tfsdk:"query"`
+	Mode        types.String `tfsdk:"mode"`
+	Result      types.Map    `tfsdk:"result"`
+	Output      types.String `tfsdk:"output"`
+	Error       types.String `tfsdk:"error"`
+	ID          types.String `tfsdk:"id"`
 }
@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/Spirit91/terraform-provider-test/internal/policy"
+)
+
+var _ provider.Provider = (*testProvider)(nil)
+
+// New returns a provider.Provider factory suitable for providerserver.NewProtocol6.
+func New() func() provider.Provider {
+	return func() provider.Provider {
+		return &testProvider{}
+	}
+}
+
+type testProvider struct{}
+
+type testProviderModel struct {
+	ProgressReporter    types.String `tfsdk:"progress_reporter"`
+	ProgressFile        types.String `tfsdk:"progress_file"`
+	ProgressSocket      types.String `tfsdk:"progress_socket"`
+	AllowedCommands     types.List   `tfsdk:"allowed_commands"`
+	DeniedCommands      types.List   `tfsdk:"denied_commands"`
+	RequireAbsolutePath types.Bool   `tfsdk:"require_absolute_path"`
+	ExecutableSHA256    types.Map    `tfsdk:"executable_sha256"`
+}
+
+// testProviderData is handed to data sources and resources via Configure so
+// they can build a progress.Reporter consistent with the configured sink and
+// enforce the configured execution policy.
+type testProviderData struct {
+	ProgressReporter string
+	ProgressFile     string
+	ProgressSocket   string
+	Policy           policy.Policy
+}
+
+func (p *testProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "test"
+}
+
+func (p *testProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Runs and inspects local commands, similar to the removed `local-exec` provisioner.",
+		Attributes: map[string]schema.Attribute{
+			"progress_reporter": schema.StringAttribute{
+				Description: "Where progress events for long-running commands are emitted: `tflog` (default), `file`, or `unix-socket`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("tflog", "file", "unix-socket"),
+				},
+			},
+			"progress_file": schema.StringAttribute{
+				Description: "Path of the file progress events are appended to, as JSON lines. Required when `progress_reporter = \"file\"`.",
+				Optional:    true,
+			},
+			"progress_socket": schema.StringAttribute{
+				Description: "Path of the Unix domain socket progress events are streamed to, as JSON lines. Required when `progress_reporter = \"unix-socket\"`.",
+				Optional:    true,
+			},
+			"allowed_commands": schema.ListAttribute{
+				Description: "Glob patterns matched against the resolved absolute path of the executable. When set, only matching executables may be run.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"denied_commands": schema.ListAttribute{
+				Description: "Glob patterns matched against the resolved absolute path of the executable. Matching executables are refused even if they also match `allowed_commands`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"require_absolute_path": schema.BoolAttribute{
+				Description: "Refuse to execute any command that does not resolve to an absolute path.",
+				Optional:    true,
+			},
+			"executable_sha256": schema.MapAttribute{
+				Description: "A map of resolved executable path to the lowercase hex SHA-256 digest it must match on disk. Execution is refused if the file's digest doesn't match.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (p *testProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config testProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sink := config.ProgressReporter.ValueString()
+	if sink == "" {
+		sink = "tflog"
+	}
+
+	var allowedCommands, deniedCommands []string
+	if !config.AllowedCommands.IsNull() && !config.AllowedCommands.IsUnknown() {
+		resp.Diagnostics.Append(config.AllowedCommands.ElementsAs(ctx, &allowedCommands, false)...)
+	}
+	if !config.DeniedCommands.IsNull() && !config.DeniedCommands.IsUnknown() {
+		resp.Diagnostics.Append(config.DeniedCommands.ElementsAs(ctx, &deniedCommands, false)...)
+	}
+
+	var executableSHA256 map[string]string
+	if !config.ExecutableSHA256.IsNull() && !config.ExecutableSHA256.IsUnknown() {
+		resp.Diagnostics.Append(config.ExecutableSHA256.ElementsAs(ctx, &executableSHA256, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data := &testProviderData{
+		ProgressReporter: sink,
+		ProgressFile:     config.ProgressFile.ValueString(),
+		ProgressSocket:   config.ProgressSocket.ValueString(),
+		Policy: policy.Policy{
+			AllowedCommands:     allowedCommands,
+			DeniedCommands:      deniedCommands,
+			RequireAbsolutePath: config.RequireAbsolutePath.ValueBool(),
+			ExecutableSHA256:    executableSHA256,
+		},
+	}
+	resp.DataSourceData = data
+	resp.ResourceData = data
+}
+
+func (p *testProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		NewTestDataSource,
+	}
+}
+
+func (p *testProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewTestResource,
+	}
+}
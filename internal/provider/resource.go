@@ -0,0 +1,462 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource              = (*testResource)(nil)
+	_ resource.ResourceWithConfigure = (*testResource)(nil)
+)
+
+func NewTestResource() resource.Resource {
+	return &testResource{}
+}
+
+// testResource runs a command as part of create, update, and destroy,
+// complementing testDataSource's read-only, plan-time execution.
+type testResource struct {
+	providerData *testProviderData
+}
+
+func (r *testResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resource"
+}
+
+func (r *testResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*testProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *testProviderData, got: %T.", req.ProviderData),
+		)
+		return
+	}
+	r.providerData = data
+}
+
+func (r *testResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Runs local commands as part of a resource's create, update, and destroy lifecycle, for cases where `local-exec` provisioner semantics are needed but a provisioner is not available.",
+		Blocks: map[string]schema.Block{
+			"create":  commandBlockSchema("Command run when the resource is created."),
+			"update":  commandBlockSchema("Command run when `working_dir`, `environment`, `sensitive_environment`, or this block change without forcing replacement."),
+			"destroy": commandBlockSchema("Command run when the resource is destroyed."),
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+		Attributes: map[string]schema.Attribute{
+			"working_dir": schema.StringAttribute{
+				Description: "Working directory shared by the create, update, and destroy commands. Defaults to the current directory.",
+				Optional:    true,
+			},
+			"environment": schema.MapAttribute{
+				Description: "Environment variables to set for the create, update, and destroy commands, in addition to the inherited process environment.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"sensitive_environment": schema.MapAttribute{
+				Description: "Same as `environment`, but values are redacted from logs and state diffs.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"triggers": schema.MapAttribute{
+				Description: "An arbitrary map of values that, when changed, forces replacement of the resource (re-running `destroy` then `create`).",
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"output": schema.StringAttribute{
+				Description: "The standard output of the most recently executed command.",
+				Computed:    true,
+			},
+			"error": schema.StringAttribute{
+				Description: "The standard error output of the most recently executed command.",
+				Computed:    true,
+			},
+			"exit_code": schema.Int64Attribute{
+				Description: "The exit code of the most recently executed command.",
+				Computed:    true,
+			},
+			"id": schema.StringAttribute{
+				Description: "The ID of the resource, always set to `-`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// commandBlockSchema returns the shared shape of the create/update/destroy
+// blocks: a string or argv-form command, mirroring testDataSource.
+func commandBlockSchema(description string) schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		Description: description,
+		Attributes: map[string]schema.Attribute{
+			"command": schema.StringAttribute{
+				Description: "The command to execute, interpreted by a shell. Exactly one of `command` or `program` must be set.",
+				Optional:    true,
+			},
+			"program": schema.ListAttribute{
+				Description: "The command and its arguments as a list, executed directly without a shell. Exactly one of `command` or `program` must be set.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"interpreter": schema.ListAttribute{
+				Description: "The interpreter and flags used to run `command`. Defaults to `[\"/bin/sh\", \"-c\"]` on Unix and `[\"cmd\", \"/C\"]` on Windows. Ignored when `program` is set.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+type testResourceModel struct {
+	Create               *testResourceCommandModel `tfsdk:"create"`
+	Update               *testResourceCommandModel `tfsdk:"update"`
+	Destroy              *testResourceCommandModel `tfsdk:"destroy"`
+	Timeouts             timeouts.Value            `tfsdk:"timeouts"`
+	WorkingDir           types.String              `tfsdk:"working_dir"`
+	Environment          types.Map                 `tfsdk:"environment"`
+	SensitiveEnvironment types.Map                 `tfsdk:"sensitive_environment"`
+	Triggers             types.Map                 `tfsdk:"triggers"`
+	Output               types.String              `tfsdk:"output"`
+	Error                types.String              `tfsdk:"error"`
+	ExitCode             types.Int64               `tfsdk:"exit_code"`
+	ID                   types.String              `tfsdk:"id"`
+}
+
+type testResourceCommandModel struct {
+	Command     types.String `tfsdk:"command"`
+	Program     types.List   `tfsdk:"program"`
+	Interpreter types.List   `tfsdk:"interpreter"`
+}
+
+func (r *testResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan testResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := plan.Timeouts.Create(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.run(ctx, "create", plan.Create, &plan, timeout, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue("-")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *testResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// The captured command output is a side effect of create/update/destroy,
+	// not something to be refreshed out-of-band; state is authoritative.
+}
+
+func (r *testResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan testResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := plan.Timeouts.Update(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.run(ctx, "update", plan.Update, &plan, timeout, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *testResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state testResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Delete(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.run(ctx, "destroy", state.Destroy, &state, timeout, &resp.Diagnostics)
+}
+
+// run executes the given lifecycle block, if configured, against model's
+// shared working_dir/environment, and records its captured
+// stdout/stderr/exit_code on model. A nil block is a no-op.
+func (r *testResource) run(ctx context.Context, stage string, block *testResourceCommandModel, model *testResourceModel, timeout time.Duration, diags *diag.Diagnostics) {
+	if block == nil {
+		return
+	}
+
+	argv, interpreter, d := resolveBlockArgv(ctx, stage, block)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	resolvedPath, err := exec.LookPath(argv[0])
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(stage).AtName("command"),
+			"Command Not Found",
+			fmt.Sprintf("The executable '%s' was not found. Ensure it's installed and accessible.", argv[0]),
+		)
+		return
+	}
+
+	hasProgram := !block.Program.IsNull() && !block.Program.IsUnknown()
+	if r.providerData != nil {
+		for _, target := range policyTargets(hasProgram, block.Command.ValueString(), resolvedPath) {
+			if err := r.providerData.Policy.Check(target); err != nil {
+				diags.AddAttributeError(
+					path.Root(stage).AtName("command"),
+					"Executable Denied By Policy",
+					fmt.Sprintf("The executable %q was refused by the provider's command policy: %s", target, err),
+				)
+				return
+			}
+		}
+	}
+
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(runCtx, resolvedPath, argv[1:]...)
+	cmd.Dir = model.WorkingDir.ValueString()
+
+	env, d := buildEnviron(ctx, model.Environment, model.SensitiveEnvironment)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	cmd.Env = env
+
+	sensitiveValues, d := mapValues(ctx, model.SensitiveEnvironment)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	ctx = tflog.MaskMessageStrings(ctx, sensitiveValues...)
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		diags.AddError("Command Setup Failed", fmt.Sprintf("Unable to attach to stdout: %s", err))
+		return
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		diags.AddError("Command Setup Failed", fmt.Sprintf("Unable to attach to stderr: %s", err))
+		return
+	}
+
+	tflog.Info(ctx, "Executing lifecycle command", map[string]interface{}{
+		"stage":       stage,
+		"argv":        argv,
+		"interpreter": interpreter,
+		"working_dir": cmd.Dir,
+	})
+
+	if err := cmd.Start(); err != nil {
+		diags.AddError("Command Execution Failed", fmt.Sprintf("Unable to start command: %s", err))
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+	var stdoutErr, stderrErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go streamToLog(ctx, stage, "stdout", stdoutPipe, &stdout, &wg, &stdoutErr)
+	go streamToLog(ctx, stage, "stderr", stderrPipe, &stderr, &wg, &stderrErr)
+	wg.Wait()
+
+	err = cmd.Wait()
+	if err == nil && stdoutErr != nil {
+		err = fmt.Errorf("reading command stdout: %w", stdoutErr)
+	}
+	if err == nil && stderrErr != nil {
+		err = fmt.Errorf("reading command stderr: %w", stderrErr)
+	}
+
+	exitCode := int64(0)
+	if err != nil {
+		exitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = int64(exitErr.ExitCode())
+		}
+	}
+
+	model.Output = types.StringValue(stdout.String())
+	model.Error = types.StringValue(stderr.String())
+	model.ExitCode = types.Int64Value(exitCode)
+
+	tflog.Info(ctx, "Executed lifecycle command", map[string]interface{}{
+		"stage":     stage,
+		"exit_code": exitCode,
+	})
+
+	if err != nil {
+		diags.AddError(
+			"Command Execution Failed",
+			fmt.Sprintf("The %q command exited with code %d.\n\nError: %s\nStderr: %s", stage, exitCode, err, stderr.String()),
+		)
+	}
+}
+
+// resolveBlockArgv applies the same command/program resolution rules as
+// testDataSource to a single lifecycle block.
+func resolveBlockArgv(ctx context.Context, stage string, block *testResourceCommandModel) (argv, interpreter []string, diags diag.Diagnostics) {
+	command := block.Command.ValueString()
+	hasProgram := !block.Program.IsNull() && !block.Program.IsUnknown()
+	hasCommand := !block.Command.IsNull() && command != ""
+
+	if hasProgram == hasCommand {
+		diags.AddAttributeError(
+			path.Root(stage),
+			"Invalid Command Configuration",
+			fmt.Sprintf("Exactly one of \"command\" or \"program\" must be specified in the %q block.", stage),
+		)
+		return nil, nil, diags
+	}
+
+	if hasProgram {
+		d := block.Program.ElementsAs(ctx, &argv, false)
+		diags.Append(d...)
+		return argv, nil, diags
+	}
+
+	if !block.Interpreter.IsNull() && !block.Interpreter.IsUnknown() {
+		d := block.Interpreter.ElementsAs(ctx, &interpreter, false)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, nil, diags
+		}
+	} else {
+		interpreter = defaultInterpreter()
+	}
+
+	argv = append(append([]string{}, interpreter...), command)
+	return argv, interpreter, diags
+}
+
+// buildEnviron merges the inherited process environment with the optional
+// environment and sensitive_environment maps.
+func buildEnviron(ctx context.Context, environment, sensitiveEnvironment types.Map) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	env := os.Environ()
+
+	for _, m := range []types.Map{environment, sensitiveEnvironment} {
+		if m.IsNull() || m.IsUnknown() {
+			continue
+		}
+		var vars map[string]string
+		diags.Append(m.ElementsAs(ctx, &vars, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		for k, v := range vars {
+			env = append(env, fmt.Sprintf("%s=%s", k, v))
+		}
+	}
+
+	return env, diags
+}
+
+// mapValues returns the values of a types.Map as a plain slice, for passing
+// to tflog.MaskMessageStrings. A null or unknown map yields no values.
+func mapValues(ctx context.Context, m types.Map) ([]string, diag.Diagnostics) {
+	if m.IsNull() || m.IsUnknown() {
+		return nil, nil
+	}
+	var vars map[string]string
+	diags := m.ElementsAs(ctx, &vars, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+	values := make([]string, 0, len(vars))
+	for _, v := range vars {
+		values = append(values, v)
+	}
+	return values, diags
+}
+
+// streamToLog copies r line by line into tflog (tagged with stage/stream) and
+// into buf, so callers can both observe progress and capture the full
+// output. Any scan error (e.g. a line exceeding maxScanTokenSize) is
+// reported through errOut.
+func streamToLog(ctx context.Context, stage, stream string, r io.Reader, buf *bytes.Buffer, wg *sync.WaitGroup, errOut *error) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScanTokenSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		tflog.Info(ctx, line, map[string]interface{}{
+			"stage":  stage,
+			"stream": stream,
+		})
+	}
+	*errOut = scanner.Err()
+}